@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Diff is the outcome of comparing two enumeration runs for a single domain.
+type Diff struct {
+	Domain        string    `json:"domain"`
+	RunID         int64     `json:"run_id"`
+	PreviousRunID int64     `json:"previous_run_id,omitempty"`
+	ComputedAt    time.Time `json:"computed_at"`
+	New           []string  `json:"new"`
+	Disappeared   []string  `json:"disappeared"`
+}
+
+// Differ tracks a snapshot of hosts for every run of the tool and computes the
+// new/disappeared hosts between runs, independently of whether the legacy or
+// normalized schema is active.
+type Differ struct {
+	db *sql.DB
+}
+
+// NewDiffer wraps db, creating the runs/run_hosts bookkeeping tables it needs.
+func NewDiffer(db *sql.DB) (*Differ, error) {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			domain TEXT NOT NULL,
+			started_at DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS run_hosts (
+			run_id INTEGER NOT NULL REFERENCES runs(id),
+			host TEXT NOT NULL,
+			PRIMARY KEY (run_id, host)
+		);`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Differ{db: db}, nil
+}
+
+// StartRun records a new run for domain and snapshots hosts as the hosts observed
+// during it, returning the new run's ID.
+func (d *Differ) StartRun(domain string, hosts []string) (int64, error) {
+	result, err := d.db.Exec(`INSERT INTO runs (domain, started_at) VALUES (?, ?);`, domain, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, host := range hosts {
+		if _, err := d.db.Exec(`INSERT OR IGNORE INTO run_hosts (run_id, host) VALUES (?, ?);`, runID, host); err != nil {
+			return 0, err
+		}
+	}
+
+	return runID, nil
+}
+
+// PruneRuns deletes all but the keep most recent runs (and their run_hosts rows) for
+// domain, so continuous/cron use of the tool doesn't grow run_hosts without bound. A
+// keep of 0 or less disables pruning.
+func (d *Differ) PruneRuns(domain string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	rows, err := d.db.Query(`SELECT id FROM runs WHERE domain = ? ORDER BY id DESC LIMIT -1 OFFSET ?;`, domain, keep)
+	if err != nil {
+		return err
+	}
+
+	var stale []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, id := range stale {
+		if _, err := d.db.Exec(`DELETE FROM run_hosts WHERE run_id = ?;`, id); err != nil {
+			return err
+		}
+		if _, err := d.db.Exec(`DELETE FROM runs WHERE id = ?;`, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// previousRunID returns the most recent run for domain before runID, or 0 if there is
+// none.
+func (d *Differ) previousRunID(domain string, runID int64) (int64, error) {
+	var previous int64
+	err := d.db.QueryRow(`SELECT id FROM runs WHERE domain = ? AND id < ? ORDER BY id DESC LIMIT 1;`, domain, runID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return previous, err
+}
+
+func (d *Differ) hostsForRun(runID int64) (map[string]bool, error) {
+	rows, err := d.db.Query(`SELECT host FROM run_hosts WHERE run_id = ?;`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := map[string]bool{}
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts[host] = true
+	}
+
+	return hosts, rows.Err()
+}
+
+// Compute diffs runID for domain against the run immediately before it.
+func (d *Differ) Compute(domain string, runID int64) (*Diff, error) {
+	previousRunID, err := d.previousRunID(domain, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.DiffRuns(domain, previousRunID, runID)
+}
+
+// DiffRuns computes the new and disappeared hosts between two arbitrary runs for
+// domain, backing the `subp diff --from --to` subcommand.
+func (d *Differ) DiffRuns(domain string, fromRunID, toRunID int64) (*Diff, error) {
+	var previous map[string]bool
+	if fromRunID != 0 {
+		var err error
+		previous, err = d.hostsForRun(fromRunID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		previous = map[string]bool{}
+	}
+
+	current, err := d.hostsForRun(toRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{
+		Domain:        domain,
+		RunID:         toRunID,
+		PreviousRunID: fromRunID,
+		ComputedAt:    time.Now(),
+	}
+
+	for host := range current {
+		if !previous[host] {
+			diff.New = append(diff.New, host)
+		}
+	}
+	for host := range previous {
+		if !current[host] {
+			diff.Disappeared = append(diff.Disappeared, host)
+		}
+	}
+
+	return diff, nil
+}
+
+// WriteReport writes diff as NDJSON, appending to outputDir/diffs/<domain>.ndjson.
+func WriteReport(diff *Diff, outputDir string) error {
+	dir := filepath.Join(outputDir, "diffs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, diff.Domain+".ndjson")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// renderTemplate substitutes {{new_count}}, {{disappeared_count}}, {{new}},
+// {{disappeared}} and {{domain}} placeholders in template with values from diff.
+func renderTemplate(template string, diff *Diff) string {
+	replacer := strings.NewReplacer(
+		"{{domain}}", diff.Domain,
+		"{{new_count}}", fmt.Sprintf("%d", len(diff.New)),
+		"{{disappeared_count}}", fmt.Sprintf("%d", len(diff.Disappeared)),
+		"{{new}}", strings.Join(diff.New, "\n"),
+		"{{disappeared}}", strings.Join(diff.Disappeared, "\n"),
+	)
+	return replacer.Replace(template)
+}
+
+// Notify POSTs diff to webhookURL, rendering template first if one was provided
+// (so the payload can be shaped for Slack/Discord's message format); otherwise it
+// posts the raw diff JSON.
+func Notify(diff *Diff, webhookURL, template string) error {
+	var body []byte
+
+	if template != "" {
+		rendered := renderTemplate(template, diff)
+		body = []byte(rendered)
+	} else {
+		var err error
+		body, err = json.Marshal(diff)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := http.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notify webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDiffer(t *testing.T) (*sql.DB, *Differ) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	differ, err := NewDiffer(db)
+	if err != nil {
+		t.Fatalf("creating differ: %v", err)
+	}
+
+	return db, differ
+}
+
+// TestDifferAcrossRepeatedRuns exercises StartRun/Compute across several invocations
+// against the same DB, as happens when the tool is cron'd for continuous monitoring.
+func TestDifferAcrossRepeatedRuns(t *testing.T) {
+	_, differ := openTestDiffer(t)
+
+	run1, err := differ.StartRun("example.com", []string{"www.example.com", "api.example.com"})
+	if err != nil {
+		t.Fatalf("StartRun 1: %v", err)
+	}
+	diff1, err := differ.Compute("example.com", run1)
+	if err != nil {
+		t.Fatalf("Compute 1: %v", err)
+	}
+	if len(diff1.New) != 2 || len(diff1.Disappeared) != 0 {
+		t.Fatalf("expected first run to report 2 new hosts, got new=%v disappeared=%v", diff1.New, diff1.Disappeared)
+	}
+
+	run2, err := differ.StartRun("example.com", []string{"www.example.com", "admin.example.com"})
+	if err != nil {
+		t.Fatalf("StartRun 2: %v", err)
+	}
+	diff2, err := differ.Compute("example.com", run2)
+	if err != nil {
+		t.Fatalf("Compute 2: %v", err)
+	}
+	if len(diff2.New) != 1 || diff2.New[0] != "admin.example.com" {
+		t.Fatalf("expected admin.example.com as the only new host, got %v", diff2.New)
+	}
+	if len(diff2.Disappeared) != 1 || diff2.Disappeared[0] != "api.example.com" {
+		t.Fatalf("expected api.example.com as the only disappeared host, got %v", diff2.Disappeared)
+	}
+}
+
+// TestPruneRunsKeepsOnlyMostRecent checks that PruneRuns caps run_hosts growth by
+// dropping everything but the most recent `keep` runs for a domain.
+func TestPruneRunsKeepsOnlyMostRecent(t *testing.T) {
+	db, differ := openTestDiffer(t)
+
+	var runIDs []int64
+	for i := 0; i < 5; i++ {
+		runID, err := differ.StartRun("example.com", []string{"www.example.com"})
+		if err != nil {
+			t.Fatalf("StartRun %d: %v", i, err)
+		}
+		runIDs = append(runIDs, runID)
+	}
+
+	if err := differ.PruneRuns("example.com", 2); err != nil {
+		t.Fatalf("PruneRuns: %v", err)
+	}
+
+	var runCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM runs WHERE domain = ?;`, "example.com").Scan(&runCount); err != nil {
+		t.Fatalf("counting runs: %v", err)
+	}
+	if runCount != 2 {
+		t.Fatalf("expected 2 runs to remain after pruning, got %d", runCount)
+	}
+
+	for _, id := range runIDs[:3] {
+		var hostCount int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM run_hosts WHERE run_id = ?;`, id).Scan(&hostCount); err != nil {
+			t.Fatalf("counting run_hosts for pruned run %d: %v", id, err)
+		}
+		if hostCount != 0 {
+			t.Fatalf("expected run_hosts for pruned run %d to be deleted, found %d rows", id, hostCount)
+		}
+	}
+
+	for _, id := range runIDs[3:] {
+		var hostCount int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM run_hosts WHERE run_id = ?;`, id).Scan(&hostCount); err != nil {
+			t.Fatalf("counting run_hosts for retained run %d: %v", id, err)
+		}
+		if hostCount == 0 {
+			t.Fatalf("expected run_hosts for retained run %d to survive pruning", id)
+		}
+	}
+}
+
+// TestPruneRunsDisabled checks that a keep of 0 leaves history untouched.
+func TestPruneRunsDisabled(t *testing.T) {
+	db, differ := openTestDiffer(t)
+
+	for i := 0; i < 3; i++ {
+		if _, err := differ.StartRun("example.com", []string{"www.example.com"}); err != nil {
+			t.Fatalf("StartRun %d: %v", i, err)
+		}
+	}
+
+	if err := differ.PruneRuns("example.com", 0); err != nil {
+		t.Fatalf("PruneRuns: %v", err)
+	}
+
+	var runCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM runs WHERE domain = ?;`, "example.com").Scan(&runCount); err != nil {
+		t.Fatalf("counting runs: %v", err)
+	}
+	if runCount != 3 {
+		t.Fatalf("expected pruning with keep=0 to be a no-op, got %d runs", runCount)
+	}
+}
@@ -0,0 +1,67 @@
+package enumerator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// AlienVaultSource queries AlienVault OTX's passive DNS API for a domain. An API key
+// is optional for this endpoint but raises the rate limit.
+type AlienVaultSource struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *AlienVaultSource) Name() string { return "alienvault" }
+
+type otxResponse struct {
+	PassiveDNS []struct {
+		Hostname string `json:"hostname"`
+	} `json:"passive_dns"`
+}
+
+// Enumerate implements Source.
+func (s *AlienVaultSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := fmt.Sprintf("https://otx.alienvault.com/api/v1/indicators/domain/%s/passive_dns", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.APIKey != "" {
+		req.Header.Set("X-OTX-API-KEY", s.APIKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		var data otxResponse
+		if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return
+		}
+
+		for _, entry := range data.PassiveDNS {
+			select {
+			case out <- Result{Host: entry.Hostname}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package enumerator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// BufferOverSource queries the BufferOver DNS dataset, which returns "ip,host" pairs
+// pulled from its own passive DNS collection.
+type BufferOverSource struct {
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *BufferOverSource) Name() string { return "bufferover" }
+
+type bufferOverResponse struct {
+	FDNSA []string `json:"FDNS_A"`
+}
+
+// Enumerate implements Source.
+func (s *BufferOverSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := "https://dns.bufferover.run/dns?q=." + domain
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		var data bufferOverResponse
+		if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return
+		}
+
+		for _, entry := range data.FDNSA {
+			parts := strings.SplitN(entry, ",", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			select {
+			case out <- Result{Host: parts[1]}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
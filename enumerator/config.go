@@ -0,0 +1,47 @@
+package enumerator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SourceConfig holds the per-source credentials and tuning read from the enumerator
+// config file.
+type SourceConfig struct {
+	APIKey     string  `json:"api_key,omitempty"`
+	RatePerSec float64 `json:"rate_per_sec,omitempty"`
+}
+
+// Config is the top-level enumerator configuration, keyed by source name (the string
+// returned by Source.Name).
+type Config struct {
+	Sources map[string]SourceConfig `json:"sources"`
+}
+
+// LoadConfig reads a JSON config file describing per-source API keys and rate limits.
+// A blank path, or a path that doesn't exist, returns an empty Config so sources that
+// need no key still work out of the box.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return &Config{Sources: map[string]SourceConfig{}}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{Sources: map[string]SourceConfig{}}, nil
+		}
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing enumerator config %s: %v", path, err)
+	}
+	if cfg.Sources == nil {
+		cfg.Sources = map[string]SourceConfig{}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,64 @@
+package enumerator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CrtShSource queries crt.sh's certificate transparency log search for names that
+// appear in issued certificates for a domain.
+type CrtShSource struct {
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *CrtShSource) Name() string { return "crtsh" }
+
+type crtShEntry struct {
+	NameValue string `json:"name_value"`
+}
+
+// Enumerate implements Source.
+func (s *CrtShSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := fmt.Sprintf("https://crt.sh/?q=%%25.%s&output=json", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		var entries []crtShEntry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			for _, line := range strings.Split(entry.NameValue, "\n") {
+				select {
+				case out <- Result{Host: line}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
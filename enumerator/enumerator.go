@@ -0,0 +1,152 @@
+package enumerator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Enumerated is the deduplicated outcome for a single host: every source that observed it.
+type Enumerated struct {
+	Host    string
+	Sources []string
+}
+
+// Enumerator fans a domain out across a set of passive Sources, rate limiting each one
+// individually, and deduplicates the results while keeping track of which sources
+// observed each host.
+type Enumerator struct {
+	sources []Source
+	config  *Config
+	workers int
+}
+
+// NewEnumerator builds an Enumerator from the given sources and config. workers bounds
+// how many sources are queried concurrently; a value <= 0 runs all sources at once.
+func NewEnumerator(sources []Source, cfg *Config, workers int) *Enumerator {
+	if cfg == nil {
+		cfg = &Config{Sources: map[string]SourceConfig{}}
+	}
+	if workers <= 0 {
+		workers = len(sources)
+	}
+	return &Enumerator{sources: sources, config: cfg, workers: workers}
+}
+
+// Run queries every configured source concurrently (bounded by the worker pool) and
+// returns the deduplicated, attribution-tagged results for domain. It returns the first
+// error encountered by any source, but still returns whatever results the other sources
+// managed to collect.
+func (e *Enumerator) Run(ctx context.Context, domain string) ([]Enumerated, error) {
+	type found struct {
+		host   string
+		source string
+	}
+
+	resultsCh := make(chan found)
+	sem := make(chan struct{}, e.workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for _, src := range e.sources {
+		src := src
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			limiter := newRateLimiter(e.config.Sources[src.Name()].RatePerSec)
+			defer limiter.stop()
+
+			if err := limiter.wait(ctx); err != nil {
+				return
+			}
+
+			ch, err := src.Enumerate(ctx, domain)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %v", src.Name(), err)
+				}
+				mu.Unlock()
+				return
+			}
+
+			for r := range ch {
+				select {
+				case resultsCh <- found{host: r.Host, source: src.Name()}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
+
+	seen := map[string]map[string]bool{}
+	var order []string
+	for f := range resultsCh {
+		host := normalizeHost(f.host, domain)
+		if host == "" {
+			continue
+		}
+		if seen[host] == nil {
+			seen[host] = map[string]bool{}
+			order = append(order, host)
+		}
+		seen[host][f.source] = true
+	}
+
+	out := make([]Enumerated, 0, len(order))
+	for _, host := range order {
+		srcs := make([]string, 0, len(seen[host]))
+		for s := range seen[host] {
+			srcs = append(srcs, s)
+		}
+		sort.Strings(srcs)
+		out = append(out, Enumerated{Host: host, Sources: srcs})
+	}
+
+	return out, firstErr
+}
+
+// normalizeHost lower-cases a raw hostname, strips wildcard/trailing-dot artifacts
+// from certificate and passive DNS data, and rejects anything that isn't actually a
+// subdomain of domain.
+func normalizeHost(host, domain string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(host, "*.")
+	host = strings.TrimSuffix(host, ".")
+
+	if host == "" {
+		return ""
+	}
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return ""
+	}
+
+	return host
+}
+
+// DefaultSources returns the built-in passive sources, configured with API keys from
+// cfg where applicable (currently only alienvault); every source uses
+// http.DefaultClient.
+func DefaultSources(cfg *Config) []Source {
+	return []Source{
+		&CrtShSource{},
+		&HackerTargetSource{},
+		&AlienVaultSource{APIKey: cfg.Sources["alienvault"].APIKey},
+		&WaybackSource{},
+		&RapidDNSSource{},
+		&BufferOverSource{},
+	}
+}
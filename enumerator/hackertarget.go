@@ -0,0 +1,54 @@
+package enumerator
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// HackerTargetSource queries the HackerTarget hostsearch API, a free passive DNS lookup
+// that returns "host,ip" pairs as plain text.
+type HackerTargetSource struct {
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *HackerTargetSource) Name() string { return "hackertarget" }
+
+// Enumerate implements Source.
+func (s *HackerTargetSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := "https://api.hackertarget.com/hostsearch/?q=" + domain
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			host := strings.SplitN(scanner.Text(), ",", 2)[0]
+			select {
+			case out <- Result{Host: host}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package enumerator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// RapidDNSSource scrapes RapidDNS's subdomain listing page; RapidDNS has no JSON API
+// for this lookup.
+type RapidDNSSource struct {
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *RapidDNSSource) Name() string { return "rapiddns" }
+
+var rapidDNSHostPattern = regexp.MustCompile(`<td>([a-zA-Z0-9_.-]+)</td>`)
+
+// Enumerate implements Source.
+func (s *RapidDNSSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := "https://rapiddns.io/subdomain/" + domain + "?full=1"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return
+		}
+
+		for _, match := range rapidDNSHostPattern.FindAllSubmatch(body, -1) {
+			select {
+			case out <- Result{Host: string(match[1])}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package enumerator
+
+import (
+	"context"
+	"time"
+)
+
+// defaultRatePerSec is used when a source has no explicit rate configured.
+const defaultRatePerSec = 5.0
+
+// rateLimiter throttles a source's requests to at most ratePerSec per second.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(ratePerSec float64) *rateLimiter {
+	if ratePerSec <= 0 {
+		ratePerSec = defaultRatePerSec
+	}
+	interval := time.Duration(float64(time.Second) / ratePerSec)
+	return &rateLimiter{ticker: time.NewTicker(interval)}
+}
+
+// wait blocks until the next slot is available or ctx is canceled.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	select {
+	case <-r.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *rateLimiter) stop() {
+	r.ticker.Stop()
+}
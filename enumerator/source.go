@@ -0,0 +1,21 @@
+package enumerator
+
+import "context"
+
+// Result is a single subdomain observation emitted by a Source.
+type Result struct {
+	Host string
+}
+
+// Source is implemented by each passive subdomain data provider. Enumerate is expected
+// to stream results as they arrive rather than buffering them, so slow sources don't
+// hold up fast ones.
+type Source interface {
+	// Name returns the short, lowercase identifier used for attribution and for looking
+	// up this source's entry in the enumerator config (API key, rate limit, ...).
+	Name() string
+
+	// Enumerate queries the source for subdomains of domain. The returned channel is
+	// closed once the source is exhausted or ctx is canceled.
+	Enumerate(ctx context.Context, domain string) (<-chan Result, error)
+}
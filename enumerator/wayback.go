@@ -0,0 +1,63 @@
+package enumerator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WaybackSource mines the Wayback Machine's CDX index for historical URLs crawled
+// under domain and extracts the hostnames they were served from.
+type WaybackSource struct {
+	HTTPClient *http.Client
+}
+
+// Name implements Source.
+func (s *WaybackSource) Name() string { return "wayback" }
+
+// Enumerate implements Source.
+func (s *WaybackSource) Enumerate(ctx context.Context, domain string) (<-chan Result, error) {
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	reqURL := fmt.Sprintf("https://web.archive.org/cdx/search/cdx?url=*.%s/*&output=text&fl=original&collapse=urlkey", domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Result)
+	go func() {
+		defer resp.Body.Close()
+		defer close(out)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			u, err := url.Parse(scanner.Text())
+			if err != nil {
+				continue
+			}
+			host := strings.ToLower(u.Hostname())
+			if host == "" {
+				continue
+			}
+			select {
+			case out <- Result{Host: host}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestNormalizedStore(t *testing.T) *NormalizedStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+
+	return NewNormalizedStore(db)
+}
+
+// TestJSONProcessorUpsertsOnDuplicateHost checks that processing a batch with the
+// same host twice (once in each of two flushed batches) updates the row in place
+// instead of erroring or creating a duplicate, exercising InsertBatch's
+// INSERT ... ON CONFLICT path with a small batch size so multiple transactions fire.
+func TestJSONProcessorUpsertsOnDuplicateHost(t *testing.T) {
+	store := newTestNormalizedStore(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.ndjson")
+	content := `{"host":"www.example.com","input":"example.com","sources":["crtsh"]}
+{"host":"api.example.com","input":"example.com","sources":["crtsh"]}
+{"host":"www.example.com","input":"example.com","sources":["subfinder"]}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing input file: %v", err)
+	}
+
+	jp := NewJSONProcessor(store, 1, nil)
+	if err := jp.ProcessFile(path); err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+
+	hosts, err := store.AllHosts("example.com")
+	if err != nil {
+		t.Fatalf("AllHosts: %v", err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 distinct hosts after upsert, got %d: %v", len(hosts), hosts)
+	}
+
+	stats := jp.Stats()
+	if stats.Rows != 3 {
+		t.Fatalf("expected 3 rows seen, got %d", stats.Rows)
+	}
+	if stats.Duplicates != 1 {
+		t.Fatalf("expected 1 duplicate row (repeated host+input), got %d", stats.Duplicates)
+	}
+
+	tables := jp.TouchedTables()
+	if len(tables) != 1 || tables[0] != "example.com" {
+		t.Fatalf("expected example.com as the only touched table, got %v", tables)
+	}
+}
+
+// TestJSONProcessorBatchesAcrossDomains checks that rows for different input domains
+// are batched and flushed independently.
+func TestJSONProcessorBatchesAcrossDomains(t *testing.T) {
+	store := newTestNormalizedStore(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.ndjson")
+	content := `{"host":"www.example.com","input":"example.com","sources":["crtsh"]}
+{"host":"www.other.com","input":"other.com","sources":["crtsh"]}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing input file: %v", err)
+	}
+
+	jp := NewJSONProcessor(store, 1000, nil)
+	if err := jp.ProcessFile(path); err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+
+	exampleHosts, err := store.AllHosts("example.com")
+	if err != nil {
+		t.Fatalf("AllHosts(example.com): %v", err)
+	}
+	if len(exampleHosts) != 1 {
+		t.Fatalf("expected 1 host under example.com, got %v", exampleHosts)
+	}
+
+	otherHosts, err := store.AllHosts("other.com")
+	if err != nil {
+		t.Fatalf("AllHosts(other.com): %v", err)
+	}
+	if len(otherHosts) != 1 {
+		t.Fatalf("expected 1 host under other.com, got %v", otherHosts)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -13,6 +14,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SecWithMoh/SubP/enumerator"
+	"github.com/SecWithMoh/SubP/output"
+	"github.com/SecWithMoh/SubP/resolver"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -35,6 +39,15 @@ func NewDBManager(dbPath string) (*DBManager, error) {
 		return nil, err
 	}
 
+	// WAL lets batched inserts commit without blocking concurrent readers, and
+	// synchronous=NORMAL is safe under WAL while avoiding an fsync per transaction.
+	if _, err := db.Exec(`PRAGMA journal_mode=WAL;`); err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`PRAGMA synchronous=NORMAL;`); err != nil {
+		return nil, err
+	}
+
 	return &DBManager{db: db}, nil
 }
 
@@ -77,9 +90,154 @@ func (manager *DBManager) CreateTable(tableName string) error {
 		}
 	}
 
+	return manager.ensureResolverColumns(tableName)
+}
+
+// resolverColumns lists the columns added to a domain table to persist resolver
+// results, alongside the SQL type used to ALTER TABLE them in.
+var resolverColumns = []struct {
+	name string
+	def  string
+}{
+	{"ip", "TEXT"},
+	{"cname", "TEXT"},
+	{"http_status", "INTEGER"},
+	{"resolved_at", "DATETIME"},
+	{"alive", "INTEGER"},
+}
+
+// ensureResolverColumns adds the ip/cname/http_status/resolved_at/alive columns to
+// tableName if they aren't already present, so older per-domain tables can pick up
+// resolver output without a full migration.
+func (manager *DBManager) ensureResolverColumns(tableName string) error {
+	rows, err := manager.db.Query(fmt.Sprintf(`PRAGMA table_info("%s");`, tableName))
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, col := range resolverColumns {
+		if existing[col.name] {
+			continue
+		}
+		query := fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN %s %s;`, tableName, col.name, col.def)
+		if _, err := manager.db.Exec(query); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// HostsForResolution returns the hosts in tableName that have never been resolved, or
+// whose resolved_at is older than refresh, so -resolve can be rerun as a delta job.
+func (manager *DBManager) HostsForResolution(tableName string, refresh time.Duration) ([]string, error) {
+	cutoff := time.Now().Add(-refresh).Format("2006-01-02 15:04:05")
+
+	query := fmt.Sprintf(`SELECT host FROM "%s" WHERE resolved_at IS NULL OR resolved_at < ?;`, tableName)
+	rows, err := manager.db.Query(query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, rows.Err()
+}
+
+// InsertBatch upserts an entire batch of entries into tableName inside a single
+// transaction, using INSERT ... ON CONFLICT instead of a SELECT-then-INSERT per row.
+// This is what lets ProcessFile stream million-row feeds without O(n) round-trips.
+func (manager *DBManager) InsertBatch(tableName string, batch []JSONData) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := manager.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO "%s" (host, input, sources, timestamp) VALUES (?, ?, ?, ?)
+		ON CONFLICT(host, input) DO UPDATE SET sources = excluded.sources, timestamp = excluded.timestamp;`, tableName)
+
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	for _, data := range batch {
+		sources := strings.Join(data.Sources, ",")
+		if _, err := stmt.Exec(data.Host, data.Input, sources, timestamp); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// AllHosts returns every host currently stored in tableName.
+func (manager *DBManager) AllHosts(tableName string) ([]string, error) {
+	rows, err := manager.db.Query(fmt.Sprintf(`SELECT host FROM "%s";`, tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, rows.Err()
+}
+
+// UpdateResolution persists a resolver.Result for host in tableName.
+func (manager *DBManager) UpdateResolution(tableName, host string, res resolver.Result) error {
+	query := fmt.Sprintf(`UPDATE "%s" SET ip = ?, cname = ?, http_status = ?, resolved_at = ?, alive = ? WHERE host = ?;`, tableName)
+
+	alive := 0
+	if res.Alive {
+		alive = 1
+	}
+
+	_, err := manager.db.Exec(query, res.IP, res.CNAME, res.HTTPStatus, res.ResolvedAt.Format("2006-01-02 15:04:05"), alive, host)
+	return err
+}
+
 // RecordExists checks if a record with the same host and input already exists in the table
 func (manager *DBManager) RecordExists(tableName, host, input string) (bool, error) {
 	query := fmt.Sprintf("SELECT 1 FROM \"%s\" WHERE host = ? AND input = ? LIMIT 1;", tableName)
@@ -118,72 +276,206 @@ func (manager *DBManager) InsertData(tableName string, data JSONData) error {
 	return nil
 }
 
+// maxScanLineBytes bounds how long a single NDJSON line can be before ProcessFile
+// gives up on it; it's well above anything a reasonable "host,input,sources" record
+// should need, but keeps a corrupt feed from growing the scan buffer unbounded.
+const maxScanLineBytes = 10 * 1024 * 1024
+
+// ProcessingStats reports the throughput of a JSONProcessor's run, printed at the end
+// so users processing million-row feeds can see whether batching is keeping up.
+type ProcessingStats struct {
+	Rows       int
+	Duplicates int
+	StartedAt  time.Time
+}
+
+// RowsPerSecond returns the processing rate so far.
+func (s ProcessingStats) RowsPerSecond() float64 {
+	elapsed := time.Since(s.StartedAt).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Rows) / elapsed
+}
+
+// DuplicateRate returns the fraction of rows seen more than once for the same
+// host+input pair within this run.
+func (s ProcessingStats) DuplicateRate() float64 {
+	if s.Rows == 0 {
+		return 0
+	}
+	return float64(s.Duplicates) / float64(s.Rows)
+}
+
 // JSONProcessor handles the processing of JSON files and data
 type JSONProcessor struct {
-	dbManager *DBManager
+	store         Store
+	writers       []output.Writer
+	touchedTables map[string]bool
+	batchSize     int
+	seen          map[string]bool
+	stats         ProcessingStats
+}
+
+// NewJSONProcessor creates a new JSONProcessor backed by store, which may be the
+// legacy *DBManager schema or the normalized *NormalizedStore schema, or nil if the
+// "sqlite" output format wasn't selected. batchSize controls how many rows are
+// upserted per transaction; a value <= 0 defaults to 1000. Every parsed row also fans
+// out to writers, so one pipeline can populate the DB and emit a flat file.
+func NewJSONProcessor(store Store, batchSize int, writers []output.Writer) *JSONProcessor {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &JSONProcessor{
+		store:         store,
+		writers:       writers,
+		touchedTables: map[string]bool{},
+		batchSize:     batchSize,
+		seen:          map[string]bool{},
+		stats:         ProcessingStats{StartedAt: time.Now()},
+	}
 }
 
-// NewJSONProcessor creates a new JSONProcessor
-func NewJSONProcessor(dbManager *DBManager) *JSONProcessor {
-	return &JSONProcessor{dbManager: dbManager}
+// TouchedTables returns the table names ProcessFile has flushed rows for so far, so callers
+// can run post-processing steps (like resolution) scoped to just those tables.
+func (jp *JSONProcessor) TouchedTables() []string {
+	tables := make([]string, 0, len(jp.touchedTables))
+	for t := range jp.touchedTables {
+		tables = append(tables, t)
+	}
+	return tables
 }
 
-// ProcessFile processes a single JSON file, handling both single and multiple JSON objects
+// Stats returns the rows-processed/duplicate-rows/throughput seen so far.
+func (jp *JSONProcessor) Stats() ProcessingStats {
+	return jp.stats
+}
+
+// ProcessFile processes a single JSON file. A file starting with '[' is read whole
+// and handled as a JSON array (the ConvertSubdomainListToJSON output); anything else
+// is streamed line-by-line as NDJSON and inserted in batches of jp.batchSize inside a
+// single transaction per batch, so million-row feeds don't pay a round-trip per row.
 func (jp *JSONProcessor) ProcessFile(filePath string) error {
-	file, err := ioutil.ReadFile(filePath)
+	file, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	var jsonDataArray []JSONData
-	if err := json.Unmarshal(file, &jsonDataArray); err != nil {
-		// Handle as newline-delimited JSON
-		return jp.processNDJSON(filePath, string(file))
-	}
-
-	// Handle as a JSON array
-	for _, jsonData := range jsonDataArray {
-		if err := jp.processJSONData(jsonData); err != nil {
+	reader := bufio.NewReader(file)
+	first, err := reader.Peek(1)
+	if err == nil && len(first) > 0 && first[0] == '[' {
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
 			return err
 		}
+
+		var jsonDataArray []JSONData
+		if err := json.Unmarshal(data, &jsonDataArray); err != nil {
+			return fmt.Errorf("error parsing JSON array in file %s: %v", filePath, err)
+		}
+
+		return jp.processRows(jsonDataArray)
 	}
 
-	return nil
-}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxScanLineBytes)
 
-func (jp *JSONProcessor) processNDJSON(filePath, fileContent string) error {
-	scanner := bufio.NewScanner(strings.NewReader(fileContent))
+	batches := map[string][]JSONData{}
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
 
-		var jsonData JSONData
-		if err := json.Unmarshal([]byte(line), &jsonData); err != nil {
+		var data JSONData
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
 			return fmt.Errorf("error parsing JSON in file %s: %v", filePath, err)
 		}
 
-		if err := jp.processJSONData(jsonData); err != nil {
-			return err
+		jp.recordSeen(data)
+		batches[data.Input] = append(batches[data.Input], data)
+
+		if len(batches[data.Input]) >= jp.batchSize {
+			batch := batches[data.Input]
+			batches[data.Input] = nil
+			if err := jp.flush(data.Input, batch); err != nil {
+				return err
+			}
 		}
 	}
-
 	if err := scanner.Err(); err != nil {
 		return err
 	}
 
+	for table, batch := range batches {
+		if err := jp.flush(table, batch); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (jp *JSONProcessor) processJSONData(jsonData JSONData) error {
-	tableName := jsonData.Input
+// processRows batches a slice of already-parsed JSONData (the JSON-array code path)
+// the same way ProcessFile batches streamed NDJSON rows.
+func (jp *JSONProcessor) processRows(rows []JSONData) error {
+	groups := map[string][]JSONData{}
+	for _, data := range rows {
+		jp.recordSeen(data)
+		groups[data.Input] = append(groups[data.Input], data)
+	}
 
-	if err := jp.dbManager.CreateTable(tableName); err != nil {
-		return err
+	for table, items := range groups {
+		for i := 0; i < len(items); i += jp.batchSize {
+			end := i + jp.batchSize
+			if end > len(items) {
+				end = len(items)
+			}
+			if err := jp.flush(table, items[i:end]); err != nil {
+				return err
+			}
+		}
 	}
 
-	if err := jp.dbManager.InsertData(tableName, jsonData); err != nil {
-		return err
+	return nil
+}
+
+func (jp *JSONProcessor) recordSeen(data JSONData) {
+	jp.stats.Rows++
+
+	key := data.Input + "\x00" + data.Host
+	if jp.seen[key] {
+		jp.stats.Duplicates++
+	} else {
+		jp.seen[key] = true
+	}
+}
+
+func (jp *JSONProcessor) flush(tableName string, batch []JSONData) error {
+	if len(batch) == 0 {
+		return nil
 	}
 
+	if jp.store != nil {
+		if err := jp.store.CreateTable(tableName); err != nil {
+			return err
+		}
+
+		if err := jp.store.InsertBatch(tableName, batch); err != nil {
+			return err
+		}
+	}
+	jp.touchedTables[tableName] = true
+
+	now := time.Now()
+	for _, data := range batch {
+		for _, w := range jp.writers {
+			if err := w.Write(output.Record{Host: data.Host, Input: data.Input, Sources: data.Sources, Timestamp: now}); err != nil {
+				return fmt.Errorf("writing to %s output: %v", w.Name(), err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -241,6 +533,115 @@ func ConvertSubdomainListToJSON(subdomainListPath, outputPath, inputDomain strin
 	return ioutil.WriteFile(outputPath, jsonData, 0644)
 }
 
+// RunEnumeration actively discovers subdomains for domain from the built-in passive
+// sources and writes them into the database through the existing InsertData pipeline,
+// so enumerated hosts end up in the same schema as JSON-ingested ones.
+func RunEnumeration(store Store, domain, configPath string, workers int) error {
+	cfg, err := enumerator.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("loading enumerator config: %v", err)
+	}
+
+	enum := enumerator.NewEnumerator(enumerator.DefaultSources(cfg), cfg, workers)
+
+	results, err := enum.Run(context.Background(), domain)
+	if err != nil {
+		fmt.Printf("Warning: enumeration finished with an error: %v\n", err)
+	}
+
+	if err := store.CreateTable(domain); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		data := JSONData{Host: r.Host, Input: domain, Sources: r.Sources}
+		if err := store.InsertData(domain, data); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Enumerated %d subdomains for %s\n", len(results), domain)
+	return nil
+}
+
+// RunResolution resolves every host in tableName to its DNS records, probes HTTP(S)
+// liveness on the configured ports, and persists the outcome via UpdateResolution.
+// Hosts whose IP matches the domain's wildcard DNS response are skipped. Only hosts
+// that have never been resolved, or whose resolved_at is older than refresh, are
+// re-resolved, so the tool can be rerun as a delta job.
+func RunResolution(store Store, tableName string, res *resolver.Resolver, refresh time.Duration) error {
+	hosts, err := store.HostsForResolution(tableName, refresh)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	wildcardIPs, err := res.DetectWildcard(context.Background(), tableName)
+	if err != nil {
+		return err
+	}
+
+	results := res.Run(context.Background(), hosts, wildcardIPs)
+
+	for _, host := range hosts {
+		result, ok := results[host]
+		if !ok {
+			continue
+		}
+		if err := store.UpdateResolution(tableName, host, result); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Resolved %d/%d hosts for %s\n", len(results), len(hosts), tableName)
+	return nil
+}
+
+// runDiffCommand implements `subp diff --db <path> --domain <domain> --from <run_id>
+// --to <run_id>`, printing the diff between two historical runs as JSON.
+func runDiffCommand(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	dbPath := fs.String("db", "", "Path to the SQLite DB file")
+	domain := fs.String("domain", "", "Domain to diff")
+	from := fs.Int64("from", 0, "Run ID to diff from (0 for the beginning of history)")
+	to := fs.Int64("to", 0, "Run ID to diff to")
+	fs.Parse(args)
+
+	if *dbPath == "" || *domain == "" || *to == 0 {
+		fmt.Println("Usage: subp diff --db <path> --domain <domain> --from <run_id> --to <run_id>")
+		os.Exit(1)
+	}
+
+	dbManager, err := NewDBManager(*dbPath)
+	if err != nil {
+		fmt.Printf("Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer dbManager.db.Close()
+
+	differ, err := NewDiffer(dbManager.db)
+	if err != nil {
+		fmt.Printf("Error initializing differ: %v\n", err)
+		os.Exit(1)
+	}
+
+	diff, err := differ.DiffRuns(*domain, *from, *to)
+	if err != nil {
+		fmt.Printf("Error computing diff: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		fmt.Printf("Error formatting diff: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(string(out))
+}
+
 // PrintUsage prints the help menu
 func PrintUsage() {
 	fmt.Println("Usage:")
@@ -254,10 +655,31 @@ func PrintUsage() {
 	fmt.Println("  -jsfile, --jsonfilename  Optional: Specify a specific JSON file to process")
 	fmt.Println("  -l, --subdomainlist Optional: Provide a file with a list of subdomains to convert to JSON and process")
 	fmt.Println("  -ind, --inputdomain Input domain to be used in the generated JSON (required with --subdomainlist)")
+	fmt.Println("  -enum <domain>      Actively enumerate subdomains for domain from passive sources")
+	fmt.Println("  -enum-config        Optional: Path to a JSON config file with per-source API keys/rate limits")
+	fmt.Println("  -enum-workers       Optional: Number of sources to query concurrently (default: all)")
+	fmt.Println("  -resolve            Resolve discovered hosts to DNS records and probe HTTP(S) liveness")
+	fmt.Println("  -ports              Optional: Comma-separated ports to probe for liveness (default: 80,443)")
+	fmt.Println("  -r                  Optional: Comma-separated custom DNS resolvers, e.g. 1.1.1.1,8.8.8.8")
+	fmt.Println("  -refresh            Optional: Re-resolve hosts last resolved before this duration ago (default: 24h)")
+	fmt.Println("  -legacy-schema      Keep using the old one-table-per-domain schema instead of the normalized schema")
+	fmt.Println("  -notify-webhook     Optional: Slack/Discord/generic webhook URL to POST the new-run diff to")
+	fmt.Println("  -notify-template    Optional: Template for the notify payload; supports {{domain}}, {{new_count}}, {{disappeared_count}}, {{new}}, {{disappeared}}")
+	fmt.Println("  -diff-retain        Optional: Number of most recent runs to keep per domain for diffing (default: 30, 0 disables pruning)")
+	fmt.Println("  -batch              Optional: Rows to upsert per transaction when ingesting NDJSON (default: 1000)")
+	fmt.Println("  -of                 Optional: Comma-separated output formats: sqlite,json,ndjson,csv,hostlist,stdout (default: sqlite)")
 	fmt.Println("  -h, --help          Show help menu")
+	fmt.Println()
+	fmt.Println("  subp diff --db <path> --domain <domain> --from <run_id> --to <run_id>")
+	fmt.Println("                      Print the new/disappeared hosts between two historical runs")
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runDiffCommand(os.Args[2:])
+		return
+	}
+
 	// Define command-line flags with both long and short options
 	inputDir := flag.String("input", "", "Directory containing JSON files")
 	flag.StringVar(inputDir, "i", "", "Directory containing JSON files")
@@ -277,6 +699,26 @@ func main() {
 	inputDomain := flag.String("inputdomain", "", "Input domain to be used in the generated JSON (required with --subdomainlist)")
 	flag.StringVar(inputDomain, "ind", "", "Input domain to be used in the generated JSON (required with --subdomainlist)")
 
+	enumDomain := flag.String("enum", "", "Actively enumerate subdomains for domain from passive sources")
+	enumConfig := flag.String("enum-config", "", "Optional: Path to a JSON config file with per-source API keys/rate limits")
+	enumWorkers := flag.Int("enum-workers", 0, "Optional: Number of sources to query concurrently (default: all)")
+
+	resolve := flag.Bool("resolve", false, "Resolve discovered hosts to DNS records and probe HTTP(S) liveness")
+	ports := flag.String("ports", "80,443", "Comma-separated ports to probe for liveness")
+	customResolvers := flag.String("r", "", "Comma-separated custom DNS resolvers, e.g. 1.1.1.1,8.8.8.8")
+	refresh := flag.Duration("refresh", 24*time.Hour, "Re-resolve hosts last resolved before this duration ago")
+
+	legacySchema := flag.Bool("legacy-schema", false, "Keep using the old one-table-per-domain schema instead of the normalized schema")
+
+	notifyWebhook := flag.String("notify-webhook", "", "Optional: Slack/Discord/generic webhook URL to POST the new-run diff to")
+	notifyTemplate := flag.String("notify-template", "", "Optional: Template for the notify payload; supports {{domain}}, {{new_count}}, {{disappeared_count}}, {{new}}, {{disappeared}}")
+
+	diffRetain := flag.Int("diff-retain", 30, "Number of most recent runs to keep per domain for diffing; older runs are pruned (0 disables pruning)")
+
+	batchSize := flag.Int("batch", 1000, "Number of rows to upsert per transaction when ingesting NDJSON")
+
+	outputFormats := flag.String("of", "sqlite", "Comma-separated output formats: sqlite,json,ndjson,csv,hostlist,stdout")
+
 	help := flag.Bool("help", false, "Show help menu")
 	flag.BoolVar(help, "h", false, "Show help menu")
 
@@ -288,8 +730,8 @@ func main() {
 		return
 	}
 
-	if *inputDir == "" || *outputDir == "" {
-		fmt.Println("Error: Input and output directories must be specified.")
+	if *outputDir == "" || (*inputDir == "" && *enumDomain == "") {
+		fmt.Println("Error: Output directory must be specified, along with either an input directory or -enum <domain>.")
 		PrintUsage()
 		os.Exit(1)
 	}
@@ -310,9 +752,53 @@ func main() {
 	}
 	defer dbManager.db.Close()
 
-	jsonProcessor := NewJSONProcessor(dbManager)
+	var store Store = dbManager
+	if !*legacySchema {
+		if err := RunMigrations(dbManager.db); err != nil {
+			fmt.Printf("Error running schema migrations: %v\n", err)
+			os.Exit(1)
+		}
+		if err := UpgradeLegacyTables(dbManager.db); err != nil {
+			fmt.Printf("Error upgrading legacy tables: %v\n", err)
+			os.Exit(1)
+		}
+		store = NewNormalizedStore(dbManager.db)
+	}
 
-	if *subdomainList != "" {
+	formats := splitNonEmpty(*outputFormats)
+	sqliteEnabled := false
+	var writers []output.Writer
+	for _, format := range formats {
+		if format == "sqlite" {
+			sqliteEnabled = true
+			continue
+		}
+		w, err := output.New(format, *outputDir)
+		if err != nil {
+			fmt.Printf("Error configuring output writer: %v\n", err)
+			os.Exit(1)
+		}
+		writers = append(writers, w)
+	}
+	defer func() {
+		for _, w := range writers {
+			w.Close()
+		}
+	}()
+
+	ingestStore := store
+	if !sqliteEnabled {
+		ingestStore = nil
+	}
+
+	jsonProcessor := NewJSONProcessor(ingestStore, *batchSize, writers)
+
+	if *enumDomain != "" {
+		if err := RunEnumeration(store, *enumDomain, *enumConfig, *enumWorkers); err != nil {
+			fmt.Printf("Error enumerating %s: %v\n", *enumDomain, err)
+			os.Exit(1)
+		}
+	} else if *subdomainList != "" {
 		if *inputDomain == "" {
 			fmt.Println("Error: --inputdomain is required when using --subdomainlist")
 			PrintUsage()
@@ -348,8 +834,111 @@ func main() {
 		}
 	}
 
+	tables := jsonProcessor.TouchedTables()
+	if *enumDomain != "" {
+		tables = append(tables, *enumDomain)
+	}
+
+	if *resolve && !sqliteEnabled {
+		fmt.Println("Skipping -resolve: it reads hosts back from the sqlite store, which -of excludes")
+	}
+
+	if *resolve && sqliteEnabled {
+		res := resolver.New(splitNonEmpty(*customResolvers), 0, 0, parsePorts(*ports))
+		for _, table := range tables {
+			if err := RunResolution(store, table, res, *refresh); err != nil {
+				fmt.Printf("Error resolving hosts for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if !sqliteEnabled {
+		fmt.Println("Skipping diff: it reads hosts back from the sqlite store, which -of excludes")
+	}
+
+	if sqliteEnabled {
+		differ, err := NewDiffer(dbManager.db)
+		if err != nil {
+			fmt.Printf("Error initializing differ: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, table := range tables {
+			hosts, err := store.AllHosts(table)
+			if err != nil {
+				fmt.Printf("Error reading hosts for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+
+			runID, err := differ.StartRun(table, hosts)
+			if err != nil {
+				fmt.Printf("Error recording run for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+
+			diff, err := differ.Compute(table, runID)
+			if err != nil {
+				fmt.Printf("Error computing diff for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+
+			if err := WriteReport(diff, *outputDir); err != nil {
+				fmt.Printf("Error writing diff report for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+
+			if err := differ.PruneRuns(table, *diffRetain); err != nil {
+				fmt.Printf("Error pruning old runs for %s: %v\n", table, err)
+				os.Exit(1)
+			}
+
+			if len(diff.New) > 0 || len(diff.Disappeared) > 0 {
+				fmt.Printf("Diff for %s (run %d): %d new, %d disappeared\n", table, runID, len(diff.New), len(diff.Disappeared))
+				if *notifyWebhook != "" {
+					if err := Notify(diff, *notifyWebhook, *notifyTemplate); err != nil {
+						fmt.Printf("Error notifying webhook for %s: %v\n", table, err)
+					}
+				}
+			}
+		}
+	}
+
+	stats := jsonProcessor.Stats()
+	if stats.Rows > 0 {
+		fmt.Printf("Processed %d rows (%.1f rows/sec, %.1f%% duplicate)\n", stats.Rows, stats.RowsPerSecond(), stats.DuplicateRate()*100)
+	}
+
 	fmt.Printf("Database saved at: %s\n", dbPath)
 }
 
+// splitNonEmpty splits a comma-separated list, dropping empty elements; it returns nil
+// for a blank input so callers can tell "not configured" apart from "configured empty".
+func splitNonEmpty(csv string) []string {
+	if strings.TrimSpace(csv) == "" {
+		return nil
+	}
 
+	var out []string
+	for _, part := range strings.Split(csv, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
 
+// parsePorts parses a comma-separated port list, skipping anything that doesn't parse
+// as a positive integer.
+func parsePorts(csv string) []int {
+	var ports []int
+	for _, part := range splitNonEmpty(csv) {
+		var port int
+		if _, err := fmt.Sscanf(part, "%d", &port); err != nil || port <= 0 {
+			continue
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
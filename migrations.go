@@ -0,0 +1,270 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// schemaMigration is one forward-only step in the normalized schema's evolution.
+type schemaMigration struct {
+	version     int
+	description string
+	up          func(*sql.Tx) error
+}
+
+var schemaMigrations = []schemaMigration{
+	{
+		version:     1,
+		description: "create normalized domains/subdomains/sources schema",
+		up:          migrateNormalizedSchemaV1,
+	},
+}
+
+func migrateNormalizedSchemaV1(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS domains (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE
+		);`,
+		`CREATE TABLE IF NOT EXISTS subdomains (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			domain_id INTEGER NOT NULL REFERENCES domains(id),
+			host TEXT NOT NULL,
+			first_seen DATETIME,
+			last_seen DATETIME,
+			ip TEXT,
+			cname TEXT,
+			http_status INTEGER,
+			resolved_at DATETIME,
+			alive INTEGER,
+			UNIQUE(domain_id, host)
+		);`,
+		`CREATE TABLE IF NOT EXISTS sources (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE
+		);`,
+		`CREATE TABLE IF NOT EXISTS subdomain_sources (
+			subdomain_id INTEGER NOT NULL REFERENCES subdomains(id),
+			source_id INTEGER NOT NULL REFERENCES sources(id),
+			seen_at DATETIME,
+			PRIMARY KEY (subdomain_id, source_id)
+		);`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunMigrations brings db up to the latest normalized schema, tracking applied
+// versions in a schema_migrations table so it's safe to call on every startup.
+func RunMigrations(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at DATETIME
+	);`); err != nil {
+		return err
+	}
+
+	applied := map[int]bool{}
+	rows, err := db.Query(`SELECT version FROM schema_migrations;`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, m := range schemaMigrations {
+		if applied[m.version] {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): %v", m.version, m.description, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?);`, m.version, time.Now().Format("2006-01-02 15:04:05")); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyTableColumns are the columns DBManager.CreateTable gives a per-domain table.
+// UpgradeLegacyTables uses their presence, not a denylist of "known-good" table
+// names, to decide whether a table is a legacy domain table worth migrating — any
+// table the program creates for its own bookkeeping (runs, schema_migrations, ...)
+// simply won't have this shape, so it's never mistaken for one.
+var legacyTableColumns = []string{"host", "input", "sources"}
+
+// UpgradeLegacyTables migrates rows out of old per-domain tables (one table per input
+// domain, created by DBManager.CreateTable) into the normalized schema. Each table is
+// migrated at most once: migrated_legacy_tables records which tables have already been
+// upgraded, so rerunning the tool against the same DB doesn't rescan every legacy
+// table from scratch every time.
+func UpgradeLegacyTables(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS migrated_legacy_tables (
+		table_name TEXT PRIMARY KEY,
+		migrated_at DATETIME
+	);`); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type='table';`)
+	if err != nil {
+		return err
+	}
+
+	var candidates []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		if !strings.HasPrefix(name, "sqlite_") {
+			candidates = append(candidates, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	store := NewNormalizedStore(db)
+
+	for _, table := range candidates {
+		alreadyMigrated, err := isLegacyTableMigrated(db, table)
+		if err != nil {
+			return err
+		}
+		if alreadyMigrated {
+			continue
+		}
+
+		isLegacy, err := isLegacyDomainTable(db, table)
+		if err != nil {
+			return err
+		}
+		if !isLegacy {
+			continue
+		}
+
+		if err := upgradeLegacyTable(db, store, table); err != nil {
+			return fmt.Errorf("upgrading legacy table %s: %v", table, err)
+		}
+
+		if _, err := db.Exec(`INSERT OR REPLACE INTO migrated_legacy_tables (table_name, migrated_at) VALUES (?, ?);`,
+			table, time.Now().Format("2006-01-02 15:04:05")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isLegacyTableMigrated(db *sql.DB, table string) (bool, error) {
+	var name string
+	err := db.QueryRow(`SELECT table_name FROM migrated_legacy_tables WHERE table_name = ?;`, table).Scan(&name)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// isLegacyDomainTable reports whether table has every column DBManager.CreateTable
+// puts on a per-domain table, which is how legacy tables are told apart from the
+// program's own bookkeeping tables (runs, run_hosts, schema_migrations, ...).
+func isLegacyDomainTable(db *sql.DB, table string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info("%s");`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	for _, col := range legacyTableColumns {
+		if !columns[col] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func upgradeLegacyTable(db *sql.DB, store *NormalizedStore, table string) error {
+	rows, err := db.Query(fmt.Sprintf(`SELECT host, input, sources FROM "%s";`, table))
+	if err != nil {
+		return err
+	}
+
+	var legacyRows []JSONData
+	for rows.Next() {
+		var host, input, sources string
+		if err := rows.Scan(&host, &input, &sources); err != nil {
+			rows.Close()
+			return err
+		}
+		legacyRows = append(legacyRows, JSONData{Host: host, Input: input, Sources: splitNonEmpty(sources)})
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	for _, data := range legacyRows {
+		if err := store.CreateTable(data.Input); err != nil {
+			return err
+		}
+		if err := store.InsertData(data.Input, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestUpgradeLegacyTablesSurvivesRepeatedRuns reproduces running the tool twice in
+// default (non-legacy-schema) mode against the same DB: once the differ has created
+// its runs/run_hosts bookkeeping tables, a second RunMigrations+UpgradeLegacyTables
+// pass must not mistake them for legacy per-domain tables.
+func TestUpgradeLegacyTablesSurvivesRepeatedRuns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer db.Close()
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("first RunMigrations: %v", err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE "example_com" (host TEXT, input TEXT, sources TEXT);`); err != nil {
+		t.Fatalf("creating legacy table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO "example_com" (host, input, sources) VALUES (?, ?, ?);`, "www.example.com", "example.com", "crtsh"); err != nil {
+		t.Fatalf("seeding legacy table: %v", err)
+	}
+
+	if _, err := NewDiffer(db); err != nil {
+		t.Fatalf("creating differ: %v", err)
+	}
+
+	if err := UpgradeLegacyTables(db); err != nil {
+		t.Fatalf("first UpgradeLegacyTables: %v", err)
+	}
+
+	var subdomainCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM subdomains WHERE host = ?;`, "www.example.com").Scan(&subdomainCount); err != nil {
+		t.Fatalf("querying subdomains: %v", err)
+	}
+	if subdomainCount != 1 {
+		t.Fatalf("expected 1 migrated subdomain, got %d", subdomainCount)
+	}
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("second RunMigrations: %v", err)
+	}
+	if err := UpgradeLegacyTables(db); err != nil {
+		t.Fatalf("second UpgradeLegacyTables (runs/run_hosts must not be treated as legacy tables): %v", err)
+	}
+}
+
+// TestUpgradeLegacyTablesSkipsAlreadyMigrated checks that a legacy table isn't
+// re-upserted once it has already been migrated.
+func TestUpgradeLegacyTablesSkipsAlreadyMigrated(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer db.Close()
+
+	if err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE "example_com" (host TEXT, input TEXT, sources TEXT);`); err != nil {
+		t.Fatalf("creating legacy table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO "example_com" (host, input, sources) VALUES (?, ?, ?);`, "www.example.com", "example.com", "crtsh"); err != nil {
+		t.Fatalf("seeding legacy table: %v", err)
+	}
+
+	if err := UpgradeLegacyTables(db); err != nil {
+		t.Fatalf("first UpgradeLegacyTables: %v", err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM "example_com";`); err != nil {
+		t.Fatalf("clearing legacy table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO "example_com" (host, input, sources) VALUES (?, ?, ?);`, "admin.example.com", "example.com", "crtsh"); err != nil {
+		t.Fatalf("seeding legacy table with new row: %v", err)
+	}
+
+	if err := UpgradeLegacyTables(db); err != nil {
+		t.Fatalf("second UpgradeLegacyTables: %v", err)
+	}
+
+	var adminCount int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM subdomains WHERE host = ?;`, "admin.example.com").Scan(&adminCount); err != nil {
+		t.Fatalf("querying subdomains: %v", err)
+	}
+	if adminCount != 0 {
+		t.Fatalf("expected admin.example.com to not be migrated since example_com was already marked migrated, got count %d", adminCount)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/SecWithMoh/SubP/resolver"
+)
+
+// NormalizedStore implements Store against the normalized domains/subdomains/sources
+// schema introduced by RunMigrations, as an alternative to DBManager's legacy
+// one-table-per-domain layout.
+type NormalizedStore struct {
+	db *sql.DB
+}
+
+// NewNormalizedStore wraps db for use with the normalized schema. Callers must run
+// RunMigrations on db first.
+func NewNormalizedStore(db *sql.DB) *NormalizedStore {
+	return &NormalizedStore{db: db}
+}
+
+// CreateTable ensures a domains row exists for tableName. The parameter is named to
+// match Store's signature, but in the normalized schema tableName is just a domain
+// name, not an actual table.
+func (s *NormalizedStore) CreateTable(tableName string) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO domains (name) VALUES (?);`, tableName)
+	return err
+}
+
+func (s *NormalizedStore) domainID(tableName string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM domains WHERE name = ?;`, tableName).Scan(&id)
+	return id, err
+}
+
+func (s *NormalizedStore) sourceID(name string) (int64, error) {
+	if _, err := s.db.Exec(`INSERT OR IGNORE INTO sources (name) VALUES (?);`, name); err != nil {
+		return 0, err
+	}
+	var id int64
+	err := s.db.QueryRow(`SELECT id FROM sources WHERE name = ?;`, name).Scan(&id)
+	return id, err
+}
+
+// InsertData records data.Host under the tableName domain, creating or refreshing its
+// subdomains row, and recording attribution for every source in data.Sources.
+func (s *NormalizedStore) InsertData(tableName string, data JSONData) error {
+	domainID, err := s.domainID(tableName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	_, err = s.db.Exec(`INSERT INTO subdomains (domain_id, host, first_seen, last_seen)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(domain_id, host) DO UPDATE SET last_seen = excluded.last_seen;`,
+		domainID, data.Host, now, now)
+	if err != nil {
+		return err
+	}
+
+	var subdomainID int64
+	if err := s.db.QueryRow(`SELECT id FROM subdomains WHERE domain_id = ? AND host = ?;`, domainID, data.Host).Scan(&subdomainID); err != nil {
+		return err
+	}
+
+	for _, src := range data.Sources {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			continue
+		}
+
+		sourceID, err := s.sourceID(src)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.db.Exec(`INSERT OR REPLACE INTO subdomain_sources (subdomain_id, source_id, seen_at) VALUES (?, ?, ?);`, subdomainID, sourceID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InsertBatch upserts an entire batch of entries for tableName inside a single
+// transaction, so streamed feeds don't pay a round-trip per row.
+func (s *NormalizedStore) InsertBatch(tableName string, batch []JSONData) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO domains (name) VALUES (?);`, tableName); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var domainID int64
+	if err := tx.QueryRow(`SELECT id FROM domains WHERE name = ?;`, tableName).Scan(&domainID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	for _, data := range batch {
+		if _, err := tx.Exec(`INSERT INTO subdomains (domain_id, host, first_seen, last_seen)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(domain_id, host) DO UPDATE SET last_seen = excluded.last_seen;`,
+			domainID, data.Host, now, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		var subdomainID int64
+		if err := tx.QueryRow(`SELECT id FROM subdomains WHERE domain_id = ? AND host = ?;`, domainID, data.Host).Scan(&subdomainID); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for _, src := range data.Sources {
+			src = strings.TrimSpace(src)
+			if src == "" {
+				continue
+			}
+
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO sources (name) VALUES (?);`, src); err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			var sourceID int64
+			if err := tx.QueryRow(`SELECT id FROM sources WHERE name = ?;`, src).Scan(&sourceID); err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			if _, err := tx.Exec(`INSERT OR REPLACE INTO subdomain_sources (subdomain_id, source_id, seen_at) VALUES (?, ?, ?);`, subdomainID, sourceID, now); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// HostsForResolution returns hosts in the tableName domain that have never been
+// resolved, or whose resolved_at is older than refresh.
+func (s *NormalizedStore) HostsForResolution(tableName string, refresh time.Duration) ([]string, error) {
+	cutoff := time.Now().Add(-refresh).Format("2006-01-02 15:04:05")
+
+	rows, err := s.db.Query(`SELECT sd.host FROM subdomains sd
+		JOIN domains d ON d.id = sd.domain_id
+		WHERE d.name = ? AND (sd.resolved_at IS NULL OR sd.resolved_at < ?);`, tableName, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, rows.Err()
+}
+
+// AllHosts returns every host currently stored under the tableName domain.
+func (s *NormalizedStore) AllHosts(tableName string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT sd.host FROM subdomains sd
+		JOIN domains d ON d.id = sd.domain_id
+		WHERE d.name = ?;`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []string
+	for rows.Next() {
+		var host string
+		if err := rows.Scan(&host); err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, rows.Err()
+}
+
+// UpdateResolution persists a resolver.Result for host under the tableName domain.
+func (s *NormalizedStore) UpdateResolution(tableName, host string, res resolver.Result) error {
+	alive := 0
+	if res.Alive {
+		alive = 1
+	}
+
+	_, err := s.db.Exec(`UPDATE subdomains SET ip = ?, cname = ?, http_status = ?, resolved_at = ?, alive = ?
+		WHERE host = ? AND domain_id = (SELECT id FROM domains WHERE name = ?);`,
+		res.IP, res.CNAME, res.HTTPStatus, res.ResolvedAt.Format("2006-01-02 15:04:05"), alive, host, tableName)
+	return err
+}
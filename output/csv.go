@@ -0,0 +1,54 @@
+package output
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+)
+
+// CSVWriter emits host,input,sources,timestamp rows, relying on encoding/csv for
+// proper quoting/escaping of fields containing commas or quotes.
+type CSVWriter struct {
+	file *os.File
+	w    *csv.Writer
+}
+
+// NewCSVWriter creates (or truncates) path, writes the header row, and returns a
+// writer over it.
+func NewCSVWriter(path string) (*CSVWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"host", "input", "sources", "timestamp"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &CSVWriter{file: f, w: w}, nil
+}
+
+// Name implements Writer.
+func (w *CSVWriter) Name() string { return "csv" }
+
+// Write implements Writer.
+func (w *CSVWriter) Write(r Record) error {
+	return w.w.Write([]string{
+		r.Host,
+		r.Input,
+		strings.Join(r.Sources, ","),
+		r.Timestamp.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// Close implements Writer.
+func (w *CSVWriter) Close() error {
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
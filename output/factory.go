@@ -0,0 +1,26 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// New builds the Writer for format, writing into outputDir. "sqlite" is not handled
+// here: it selects the existing DBManager/NormalizedStore pipeline rather than a flat
+// file, so callers should filter it out of the format list before calling New.
+func New(format, outputDir string) (Writer, error) {
+	switch format {
+	case "ndjson":
+		return NewNDJSONWriter(filepath.Join(outputDir, "results.ndjson"))
+	case "json":
+		return NewJSONWriter(filepath.Join(outputDir, "results.json"))
+	case "csv":
+		return NewCSVWriter(filepath.Join(outputDir, "results.csv"))
+	case "hostlist":
+		return NewHostlistWriter(filepath.Join(outputDir, "hosts.txt"))
+	case "stdout":
+		return NewStdoutWriter(), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
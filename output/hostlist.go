@@ -0,0 +1,46 @@
+package output
+
+import (
+	"bufio"
+	"os"
+)
+
+// HostlistWriter emits one bare hostname per line, deduplicated across every input
+// domain, so the file can be piped straight into tools like httpx or nuclei.
+type HostlistWriter struct {
+	file *os.File
+	w    *bufio.Writer
+	seen map[string]bool
+}
+
+// NewHostlistWriter creates (or truncates) path and returns a writer over it.
+func NewHostlistWriter(path string) (*HostlistWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &HostlistWriter{file: f, w: bufio.NewWriter(f), seen: map[string]bool{}}, nil
+}
+
+// Name implements Writer.
+func (w *HostlistWriter) Name() string { return "hostlist" }
+
+// Write implements Writer.
+func (w *HostlistWriter) Write(r Record) error {
+	if w.seen[r.Host] {
+		return nil
+	}
+	w.seen[r.Host] = true
+
+	_, err := w.w.WriteString(r.Host + "\n")
+	return err
+}
+
+// Close implements Writer.
+func (w *HostlistWriter) Close() error {
+	if err := w.w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
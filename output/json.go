@@ -0,0 +1,43 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// JSONWriter buffers every record in memory and writes them out as a single JSON
+// array on Close, mirroring the array format ProcessFile accepts.
+type JSONWriter struct {
+	path    string
+	records []jsonRecord
+}
+
+type jsonRecord struct {
+	Host    string   `json:"host"`
+	Input   string   `json:"input"`
+	Sources []string `json:"sources"`
+}
+
+// NewJSONWriter returns a writer that will write its buffered records to path on
+// Close.
+func NewJSONWriter(path string) (*JSONWriter, error) {
+	return &JSONWriter{path: path}, nil
+}
+
+// Name implements Writer.
+func (w *JSONWriter) Name() string { return "json" }
+
+// Write implements Writer.
+func (w *JSONWriter) Write(r Record) error {
+	w.records = append(w.records, jsonRecord{Host: r.Host, Input: r.Input, Sources: r.Sources})
+	return nil
+}
+
+// Close implements Writer.
+func (w *JSONWriter) Close() error {
+	data, err := json.Marshal(w.records)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.path, data, 0644)
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// NDJSONWriter appends one JSON object per line, matching the format SubP itself
+// accepts as input.
+type NDJSONWriter struct {
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewNDJSONWriter creates (or truncates) path and returns a writer over it.
+func NewNDJSONWriter(path string) (*NDJSONWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &NDJSONWriter{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Name implements Writer.
+func (w *NDJSONWriter) Name() string { return "ndjson" }
+
+// Write implements Writer.
+func (w *NDJSONWriter) Write(r Record) error {
+	return w.enc.Encode(struct {
+		Host    string   `json:"host"`
+		Input   string   `json:"input"`
+		Sources []string `json:"sources"`
+	}{Host: r.Host, Input: r.Input, Sources: r.Sources})
+}
+
+// Close implements Writer.
+func (w *NDJSONWriter) Close() error {
+	return w.file.Close()
+}
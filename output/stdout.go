@@ -0,0 +1,22 @@
+package output
+
+import "fmt"
+
+// StdoutWriter prints each discovered host to stdout as it's written, for piping the
+// live run straight into another command.
+type StdoutWriter struct{}
+
+// NewStdoutWriter returns a Writer that prints to stdout.
+func NewStdoutWriter() *StdoutWriter { return &StdoutWriter{} }
+
+// Name implements Writer.
+func (w *StdoutWriter) Name() string { return "stdout" }
+
+// Write implements Writer.
+func (w *StdoutWriter) Write(r Record) error {
+	_, err := fmt.Println(r.Host)
+	return err
+}
+
+// Close implements Writer.
+func (w *StdoutWriter) Close() error { return nil }
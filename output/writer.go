@@ -0,0 +1,24 @@
+// Package output provides pluggable sinks for discovered subdomain records, so a
+// single pipeline can populate a queryable SQLite DB and emit a flat file (for piping
+// into tools like httpx or nuclei) at the same time.
+package output
+
+import "time"
+
+// Record is one discovered subdomain, independent of whatever persistence schema
+// (legacy or normalized) the SQLite side is using.
+type Record struct {
+	Host      string
+	Input     string
+	Sources   []string
+	Timestamp time.Time
+}
+
+// Writer is implemented by every output format. Write is called once per record;
+// Close flushes and releases any underlying resource (file handle, buffer, ...) and
+// must be safe to call exactly once after the last Write.
+type Writer interface {
+	Name() string
+	Write(r Record) error
+	Close() error
+}
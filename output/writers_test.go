@@ -0,0 +1,153 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.ndjson")
+	w, err := NewNDJSONWriter(path)
+	if err != nil {
+		t.Fatalf("NewNDJSONWriter: %v", err)
+	}
+
+	records := []Record{
+		{Host: "www.example.com", Input: "example.com", Sources: []string{"crtsh"}, Timestamp: time.Now()},
+		{Host: "api.example.com", Input: "example.com", Sources: []string{"crtsh", "subfinder"}, Timestamp: time.Now()},
+	}
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), string(data))
+	}
+
+	var decoded struct {
+		Host    string   `json:"host"`
+		Input   string   `json:"input"`
+		Sources []string `json:"sources"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("decoding first line: %v", err)
+	}
+	if decoded.Host != "www.example.com" || len(decoded.Sources) != 1 {
+		t.Fatalf("unexpected decoded record: %+v", decoded)
+	}
+}
+
+func TestJSONWriterWritesArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	w, err := NewJSONWriter(path)
+	if err != nil {
+		t.Fatalf("NewJSONWriter: %v", err)
+	}
+
+	if err := w.Write(Record{Host: "www.example.com", Input: "example.com", Sources: []string{"crtsh"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Write(Record{Host: "api.example.com", Input: "example.com", Sources: nil}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+
+	var decoded []struct {
+		Host  string `json:"host"`
+		Input string `json:"input"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("output is not a JSON array: %v (data: %s)", err, data)
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(decoded))
+	}
+}
+
+func TestCSVWriterEscapesSpecialCharacters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	w, err := NewCSVWriter(path)
+	if err != nil {
+		t.Fatalf("NewCSVWriter: %v", err)
+	}
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := w.Write(Record{Host: "www.example.com", Input: "example.com", Sources: []string{"crtsh", "virustotal"}, Timestamp: ts}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Write(Record{Host: "weird,\"host\".example.com", Input: "example.com", Sources: []string{"crtsh"}, Timestamp: ts}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines: %q", len(lines), string(data))
+	}
+	if lines[0] != "host,input,sources,timestamp" {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+	if lines[1] != `www.example.com,example.com,"crtsh,virustotal",2026-01-02 03:04:05` {
+		t.Fatalf("unexpected first row: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], `"weird,""host"".example.com"`) {
+		t.Fatalf("expected host with comma/quote to be CSV-escaped, got: %q", lines[2])
+	}
+}
+
+func TestHostlistWriterDedupes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	w, err := NewHostlistWriter(path)
+	if err != nil {
+		t.Fatalf("NewHostlistWriter: %v", err)
+	}
+
+	hosts := []string{"www.example.com", "api.example.com", "www.example.com"}
+	for _, h := range hosts {
+		if err := w.Write(Record{Host: h, Input: "example.com"}); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected duplicate host to be deduped to 2 lines, got %d: %q", len(lines), string(data))
+	}
+}
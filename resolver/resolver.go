@@ -0,0 +1,199 @@
+// Package resolver resolves hosts to DNS records and probes them for HTTP(S)
+// liveness, filtering out hosts that only resolve because of a wildcard DNS record.
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Result is the outcome of resolving and probing a single host.
+type Result struct {
+	Host       string
+	IP         string
+	CNAME      string
+	HTTPStatus int
+	Alive      bool
+	ResolvedAt time.Time
+}
+
+// Resolver resolves hosts to A/AAAA/CNAME records and probes HTTP(S) liveness on a
+// configurable set of ports, using a worker pool and an optional custom resolver list.
+type Resolver struct {
+	Resolvers []string
+	Workers   int
+	Timeout   time.Duration
+	Ports     []int
+
+	resolver *net.Resolver
+	client   *http.Client
+}
+
+// New builds a Resolver. resolvers is a list of "host:port" or bare IP DNS servers to
+// use instead of the system resolver, dialed in turn; an empty list falls back to the
+// system resolver.
+// A workers value <= 0 defaults to 10, and an empty ports list defaults to 80 and 443.
+func New(resolvers []string, workers int, timeout time.Duration, ports []int) *Resolver {
+	if workers <= 0 {
+		workers = 10
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if len(ports) == 0 {
+		ports = []int{80, 443}
+	}
+
+	r := &Resolver{Resolvers: resolvers, Workers: workers, Timeout: timeout, Ports: ports}
+
+	if len(resolvers) > 0 {
+		servers := make([]string, len(resolvers))
+		for i, server := range resolvers {
+			if _, _, err := net.SplitHostPort(server); err != nil {
+				server = net.JoinHostPort(server, "53")
+			}
+			servers[i] = server
+		}
+		var next uint64
+		r.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				server := servers[(atomic.AddUint64(&next, 1)-1)%uint64(len(servers))]
+				d := net.Dialer{Timeout: timeout}
+				return d.DialContext(ctx, network, server)
+			},
+		}
+	} else {
+		r.resolver = net.DefaultResolver
+	}
+
+	r.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return r
+}
+
+// DetectWildcard resolves a handful of random, almost-certainly-nonexistent
+// subdomains of domain and returns the set of IPs they resolve to. Any result that
+// later matches one of these IPs is a wildcard DNS artifact, not a real host.
+func (r *Resolver) DetectWildcard(ctx context.Context, domain string) (map[string]bool, error) {
+	wildcardIPs := map[string]bool{}
+
+	for i := 0; i < 3; i++ {
+		probe := fmt.Sprintf("subp-wildcard-check-%d.%s", rand.Int63(), domain)
+
+		ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+		ips, err := r.resolver.LookupHost(ctx, probe)
+		cancel()
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			wildcardIPs[ip] = true
+		}
+	}
+
+	return wildcardIPs, nil
+}
+
+// Resolve looks up A/AAAA and CNAME records for host and probes HTTP(S) liveness on
+// the configured ports, returning at the first live port found.
+func (r *Resolver) Resolve(ctx context.Context, host string) (Result, error) {
+	result := Result{Host: host, ResolvedAt: time.Now()}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+	ips, err := r.resolver.LookupHost(lookupCtx, host)
+	cancel()
+	if err != nil || len(ips) == 0 {
+		return result, nil
+	}
+	result.IP = ips[0]
+
+	cnameCtx, cancel := context.WithTimeout(ctx, r.Timeout)
+	if cname, err := r.resolver.LookupCNAME(cnameCtx, host); err == nil {
+		result.CNAME = cname
+	}
+	cancel()
+
+	for _, port := range r.Ports {
+		status, ok := r.probeHTTP(ctx, host, port)
+		if ok {
+			result.HTTPStatus = status
+			result.Alive = true
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Resolver) probeHTTP(ctx context.Context, host string, port int) (int, bool) {
+	scheme := "http"
+	if port == 443 || port == 8443 {
+		scheme = "https"
+	}
+
+	url := fmt.Sprintf("%s://%s:%d", scheme, host, port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, true
+}
+
+// Run resolves hosts concurrently using the worker pool, discarding any result whose
+// IP matches a known wildcard IP.
+func (r *Resolver) Run(ctx context.Context, hosts []string, wildcardIPs map[string]bool) map[string]Result {
+	jobs := make(chan string)
+	results := make(map[string]Result, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < r.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				res, err := r.Resolve(ctx, host)
+				if err != nil {
+					continue
+				}
+				if res.IP != "" && wildcardIPs[res.IP] {
+					continue
+				}
+				mu.Lock()
+				results[host] = res
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, host := range hosts {
+		jobs <- host
+	}
+	close(jobs)
+
+	wg.Wait()
+	return results
+}
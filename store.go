@@ -0,0 +1,20 @@
+package main
+
+import (
+	"time"
+
+	"github.com/SecWithMoh/SubP/resolver"
+)
+
+// Store is the persistence boundary used by JSONProcessor, RunEnumeration and
+// RunResolution. Both DBManager (the legacy one-table-per-domain schema) and
+// NormalizedStore (the relational domains/subdomains/sources schema) implement it, so
+// the rest of the tool doesn't need to care which is active.
+type Store interface {
+	CreateTable(tableName string) error
+	InsertData(tableName string, data JSONData) error
+	InsertBatch(tableName string, batch []JSONData) error
+	HostsForResolution(tableName string, refresh time.Duration) ([]string, error)
+	UpdateResolution(tableName, host string, res resolver.Result) error
+	AllHosts(tableName string) ([]string, error)
+}